@@ -36,46 +36,78 @@ func (b BondStatus) Equal(b2 BondStatus) bool {
 
 // validator for a delegated proof of stake system
 type Validator interface {
-	GetJailed() bool                 // whether the validator is jailed
-	GetMoniker() string              // moniker of the validator
-	GetStatus() BondStatus           // status of the validator
-	GetFeeAddr() AccAddress          // fee address of validator
-	GetOperator() ValAddress         // operator address to receive/return validators coins
-	GetConsPubKey() crypto.PubKey    // validation consensus pubkey
-	GetConsAddr() ConsAddress        // validation consensus address
-	GetPower() Dec                   // validation power
-	GetTokens() Dec                  // validation tokens
-	TokensFromShares(shares Dec) Dec // calculate the token worth of provided shares
-	GetCommission() Dec              // validator commission rate
-	GetDelegatorShares() Dec         // Total out standing delegator shares
-	GetBondHeight() int64            // height in which the validator became active
-	GetSideChainConsAddr() []byte    // validation consensus address on side chain
-	GetSideChainVoteAddr() []byte    // validation vote address on side chain
-	IsSideChainValidator() bool      // if it belongs to side chain
+	GetJailed() bool                   // whether the validator is jailed
+	GetMoniker() string                // moniker of the validator
+	GetStatus() BondStatus             // status of the validator
+	GetFeeAddr() AccAddress            // fee address of validator
+	GetOperator() ValAddress           // operator address to receive/return validators coins
+	GetConsPubKey() crypto.PubKey      // validation consensus pubkey
+	GetConsAddr() ConsAddress          // validation consensus address
+	GetPower() Int                     // validation power, in whole units (tokens / PowerReduction)
+	GetTokens() Dec                    // validation tokens, summed across all bonded denoms
+	GetTokensByDenom(denom string) Dec // validation tokens bonded in a particular denom
+	GetBondedCoins() Coins             // all tokens bonded to this validator, by denom
+	TokensFromShares(shares Dec) Dec   // calculate the token worth of provided shares
+	GetCommission() Dec                // validator commission rate
+	GetDelegatorShares() Dec           // Total out standing delegator shares
+	GetBondHeight() int64              // height in which the validator became active
+	GetSideChainConsAddr() []byte      // validation consensus address on side chain
+	GetSideChainVoteAddr() []byte      // validation vote address on side chain
+	IsSideChainValidator() bool        // if it belongs to side chain
+	GetIntraTxCounter() int16          // tertiary tie-break for validators with identical voting power
 }
 
 // validator which fulfills abci validator interface for use in Tendermint
 func ABCIValidator(v Validator) abci.Validator {
 	return abci.Validator{
 		Address: v.GetConsPubKey().Address(),
-		Power:   v.GetPower().RawInt(),
+		Power:   v.GetPower().Int64(),
 	}
 }
 
+// ValidatorsByPower implements sort.Interface for a slice of Validators,
+// ranking by descending power, then operator address, then IntraTxCounter.
+// The IntraTxCounter tie-break keeps the bonded-set ordering deterministic
+// even when two validators are created or re-powered in the same block and
+// their operator addresses collide in bech32 prefixes - common during
+// genesis and side-chain validator onboarding.
+type ValidatorsByPower []Validator
+
+// nolint
+func (v ValidatorsByPower) Len() int      { return len(v) }
+func (v ValidatorsByPower) Swap(i, j int) { v[i], v[j] = v[j], v[i] }
+func (v ValidatorsByPower) Less(i, j int) bool {
+	if p1, p2 := v[i].GetPower(), v[j].GetPower(); !p1.Equal(p2) {
+		return p1.GT(p2)
+	}
+	if a1, a2 := v[i].GetOperator().String(), v[j].GetOperator().String(); a1 != a2 {
+		return a1 < a2
+	}
+	return v[i].GetIntraTxCounter() < v[j].GetIntraTxCounter()
+}
+
 // properties for the set of all validators
+//
+// GetPower and TotalPower return whole-unit sdk.Int rather than sdk.Dec: a
+// validator's raw voting power is tokens / Params.PowerReduction, truncated
+// to an integer, and round-tripping that through Dec was lossy right at the
+// PowerReduction boundary. Params.PowerReduction itself still lives in
+// x/stake/types outside this chunk.
 type ValidatorSet interface {
 	// iterate through validators by operator address, execute func for each validator
 	IterateValidators(Context,
 		func(index int64, validator Validator) (stop bool))
 
-	// iterate through bonded validators by operator address, execute func for each validator
+	// iterate through bonded validators, execute func for each validator.
+	// Validators are ranked by (power, operator-address, IntraTxCounter); see
+	// ValidatorsByPower for the comparator this ordering follows.
 	IterateValidatorsBonded(Context,
 		func(index int64, validator Validator) (stop bool))
 
 	Validator(Context, ValAddress) Validator            // get a particular validator by operator address
 	ValidatorByConsAddr(Context, ConsAddress) Validator // get a particular validator by consensus address
 	ValidatorByVoteAddr(Context, []byte) Validator      // get a particular validator by vote address
-	TotalPower(Context) Dec                             // total power of the validator set
+	TotalPower(Context) Int                             // total power of the validator set, in whole units
 
 	// slash the validator and delegators of the validator, specifying offence height, offence power, and slash fraction
 	Slash(Context, ConsAddress, int64, int64, Dec)
@@ -87,6 +119,10 @@ type ValidatorSet interface {
 	Delegation(Context, AccAddress, ValAddress) Delegation
 
 	// functions for side chain
+	// ValidatorBySideChainConsAddr looks up a validator by its side-chain
+	// consensus address; callers ranking among onboarding candidates (e.g.
+	// MsgCreateSideChainValidatorWithVoteAddr handling several validators in
+	// the same block) should break ties with ValidatorsByPower.
 	ValidatorBySideChainConsAddr(Context, []byte) Validator
 	UnjailSideChain(Context, []byte)
 	SlashSideChain(ctx Context, sideChainId string, sideConsAddr []byte, slashAmount Dec) (validator Validator, slashedAmount Dec, err error)
@@ -95,6 +131,13 @@ type ValidatorSet interface {
 	AllocateSlashAmtToValidators(ctx Context, slashedConsAddr []byte, amount Dec) (bool, map[string]int64, error)
 
 	MinSelfDelegation(ctx Context) int64 // validator minimum self delegation
+
+	// BondDenoms returns every denom a validator is allowed to accept bonded
+	// delegations in, in the priority order configured in Params.
+	BondDenoms(ctx Context) []string
+	// BondDenom is a compatibility helper returning the primary (first
+	// configured) bond denom; kept for callers that only ever dealt with a
+	// single-denom chain.
 	BondDenom(ctx Context) string
 }
 
@@ -102,9 +145,10 @@ type ValidatorSet interface {
 
 // delegation bond for a delegated proof of stake system
 type Delegation interface {
-	GetDelegatorAddr() AccAddress // delegator AccAddress for the bond
-	GetValidatorAddr() ValAddress // validator operator address
-	GetShares() Dec               // amount of validator's shares held in this delegation
+	GetDelegatorAddr() AccAddress      // delegator AccAddress for the bond
+	GetValidatorAddr() ValAddress      // validator operator address
+	GetShares() Dec                    // amount of validator's shares held in this delegation, summed across all bonded denoms
+	GetSharesByDenom(denom string) Dec // amount of validator's shares held in this delegation for a particular bonded denom
 }
 
 // properties for the set of all delegations for a particular
@@ -135,9 +179,14 @@ type StakingHooks interface {
 	OnValidatorBonded(ctx Context, address ConsAddress, operator ValAddress)         // Must be called when a validator is bonded
 	OnValidatorBeginUnbonding(ctx Context, address ConsAddress, operator ValAddress) // Must be called when a validator begins unbonding
 
-	OnDelegationCreated(ctx Context, delAddr AccAddress, valAddr ValAddress)        // Must be called when a delegation is created
-	OnDelegationSharesModified(ctx Context, delAddr AccAddress, valAddr ValAddress) // Must be called when a delegation's shares are modified
-	OnDelegationRemoved(ctx Context, delAddr AccAddress, valAddr ValAddress)        // Must be called when a delegation is removed
+	OnDelegationCreated(ctx Context, delAddr AccAddress, valAddr ValAddress) // Must be called when a delegation is created
+	// OnDelegationSharesModified must be called when a delegation's shares are
+	// modified; denom identifies which bonded denom's shares changed, so
+	// observers that track per-denom accounting (e.g. distribution's
+	// DelegatorStartingInfo.Denom) can record it instead of losing the
+	// information to the aggregate GetShares()/GetTokens() view.
+	OnDelegationSharesModified(ctx Context, delAddr AccAddress, valAddr ValAddress, denom string)
+	OnDelegationRemoved(ctx Context, delAddr AccAddress, valAddr ValAddress) // Must be called when a delegation is removed
 
 	OnSideChainValidatorBonded(ctx Context, sideConsAddr []byte, operator ValAddress)
 	OnSideChainValidatorBeginUnbonding(ctx Context, sideConsAddr []byte, operator ValAddress)
@@ -0,0 +1,64 @@
+package types
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/crypto"
+)
+
+// mockValidator is the minimal Validator double needed to exercise
+// ValidatorsByPower; unused getters return zero values.
+type mockValidator struct {
+	operator       ValAddress
+	power          Int
+	intraTxCounter int16
+}
+
+func (v mockValidator) GetJailed() bool                 { return false }
+func (v mockValidator) GetMoniker() string              { return "" }
+func (v mockValidator) GetStatus() BondStatus           { return Bonded }
+func (v mockValidator) GetFeeAddr() AccAddress          { return nil }
+func (v mockValidator) GetOperator() ValAddress         { return v.operator }
+func (v mockValidator) GetConsPubKey() crypto.PubKey    { return nil }
+func (v mockValidator) GetConsAddr() ConsAddress        { return ConsAddress(v.operator) }
+func (v mockValidator) GetPower() Int                   { return v.power }
+func (v mockValidator) GetTokens() Dec                  { return ZeroDec() }
+func (v mockValidator) GetTokensByDenom(_ string) Dec   { return ZeroDec() }
+func (v mockValidator) GetBondedCoins() Coins           { return nil }
+func (v mockValidator) TokensFromShares(shares Dec) Dec { return shares }
+func (v mockValidator) GetCommission() Dec              { return ZeroDec() }
+func (v mockValidator) GetDelegatorShares() Dec         { return ZeroDec() }
+func (v mockValidator) GetBondHeight() int64            { return 0 }
+func (v mockValidator) GetSideChainConsAddr() []byte    { return nil }
+func (v mockValidator) GetSideChainVoteAddr() []byte    { return nil }
+func (v mockValidator) IsSideChainValidator() bool      { return false }
+func (v mockValidator) GetIntraTxCounter() int16        { return v.intraTxCounter }
+
+// TestValidatorsByPowerIntraTxCounterTiebreak covers the scenario called out by the
+// request: two validators created in the same block with identical voting power and
+// colliding operator addresses must still sort deterministically, by IntraTxCounter.
+func TestValidatorsByPowerIntraTxCounterTiebreak(t *testing.T) {
+	addr := ValAddress([]byte("duplicate-operator-address"))
+	first := mockValidator{operator: addr, power: NewInt(10), intraTxCounter: 0}
+	second := mockValidator{operator: addr, power: NewInt(10), intraTxCounter: 1}
+
+	vals := ValidatorsByPower{second, first}
+	sort.Sort(vals)
+
+	require.Equal(t, int16(0), vals[0].GetIntraTxCounter(), "validator with the lower IntraTxCounter should sort first")
+	require.Equal(t, int16(1), vals[1].GetIntraTxCounter())
+}
+
+// TestValidatorsByPowerRanksByPowerFirst covers the primary sort key: higher power sorts
+// first regardless of IntraTxCounter.
+func TestValidatorsByPowerRanksByPowerFirst(t *testing.T) {
+	strong := mockValidator{operator: ValAddress([]byte("validator-a")), power: NewInt(100), intraTxCounter: 5}
+	weak := mockValidator{operator: ValAddress([]byte("validator-b")), power: NewInt(1), intraTxCounter: 0}
+
+	vals := ValidatorsByPower{weak, strong}
+	sort.Sort(vals)
+
+	require.Equal(t, strong.GetOperator(), vals[0].GetOperator())
+}
@@ -0,0 +1,46 @@
+package keeper
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/distribution/types"
+)
+
+// NewHandler returns a handler for all distribution messages, routing
+// MsgWithdrawDelegatorReward and MsgWithdrawValidatorCommission to the keeper.
+func NewHandler(k Keeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		switch msg := msg.(type) {
+		case types.MsgWithdrawDelegatorReward:
+			return handleMsgWithdrawDelegatorReward(ctx, msg, k)
+		case types.MsgWithdrawValidatorCommission:
+			return handleMsgWithdrawValidatorCommission(ctx, msg, k)
+		default:
+			errMsg := fmt.Sprintf("unrecognized distribution message type: %T", msg)
+			return sdk.ErrUnknownRequest(errMsg).Result()
+		}
+	}
+}
+
+func handleMsgWithdrawDelegatorReward(ctx sdk.Context, msg types.MsgWithdrawDelegatorReward, k Keeper) sdk.Result {
+	if err := msg.ValidateBasic(); err != nil {
+		return err.Result()
+	}
+	paid, err := k.WithdrawDelegationRewards(ctx, msg.DelegatorAddr, msg.ValidatorAddr)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Data: k.cdc.MustMarshalBinaryLengthPrefixed(paid)}
+}
+
+func handleMsgWithdrawValidatorCommission(ctx sdk.Context, msg types.MsgWithdrawValidatorCommission, k Keeper) sdk.Result {
+	if err := msg.ValidateBasic(); err != nil {
+		return err.Result()
+	}
+	paid, err := k.WithdrawValidatorCommission(ctx, msg.ValidatorAddr)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Data: k.cdc.MustMarshalBinaryLengthPrefixed(paid)}
+}
@@ -0,0 +1,92 @@
+package keeper
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Hooks wraps a distribution Keeper to satisfy sdk.StakingHooks, closing out
+// and re-opening validator/delegation reward periods whenever the staking
+// module reports that shares or bonding status have changed.
+type Hooks struct {
+	k Keeper
+}
+
+var _ sdk.StakingHooks = Hooks{}
+
+// Hooks returns the wrapper for the staking keeper to call into
+func (k Keeper) Hooks() Hooks {
+	return Hooks{k}
+}
+
+// OnValidatorCreated initializes a new validator's reward tracking at period 0
+func (h Hooks) OnValidatorCreated(ctx sdk.Context, val sdk.ValAddress) {
+	h.k.initializeValidator(ctx, val)
+}
+
+// OnValidatorModified is a no-op: AllocateTokens reads the commission rate each
+// time it allocates fees, so a rate change applies from the next allocation
+func (h Hooks) OnValidatorModified(ctx sdk.Context, val sdk.ValAddress) {}
+
+// OnValidatorRemoved is a no-op: the validator's historical reward records are
+// pruned as their reference counts drop to zero via the normal withdrawal flow;
+// its current rewards, accumulated commission and latest historical record are
+// not removed
+func (h Hooks) OnValidatorRemoved(ctx sdk.Context, val sdk.ValAddress) {}
+
+// OnValidatorBonded closes out the validator's reward period so voting power
+// starts accruing rewards from a clean starting point
+func (h Hooks) OnValidatorBonded(ctx sdk.Context, consAddr sdk.ConsAddress, val sdk.ValAddress) {
+	h.k.incrementValidatorPeriod(ctx, h.k.stakeKeeper.Validator(ctx, val))
+}
+
+// OnValidatorBeginUnbonding closes out the validator's reward period before its
+// tokens stop earning voting power
+func (h Hooks) OnValidatorBeginUnbonding(ctx sdk.Context, consAddr sdk.ConsAddress, val sdk.ValAddress) {
+	h.k.incrementValidatorPeriod(ctx, h.k.stakeKeeper.Validator(ctx, val))
+}
+
+// OnDelegationCreated sets up starting info for a brand new delegation
+func (h Hooks) OnDelegationCreated(ctx sdk.Context, delAddr sdk.AccAddress, valAddr sdk.ValAddress) {
+	if h.k.GetValidatorCurrentRewards(ctx, valAddr).Period == 0 {
+		h.k.initializeValidator(ctx, valAddr)
+	}
+	h.k.initializeDelegation(ctx, valAddr, delAddr, "")
+}
+
+// OnDelegationSharesModified pays out the delegation's reward accrued up to now, then
+// re-initializes its starting info at the validator's newly-closed period so the changed
+// share amount only starts earning from this point forward. denom is recorded on the
+// fresh starting info so a later query can tell which bonded denom drove the resync;
+// reward accounting itself still runs off the aggregate shares/tokens view, since fees
+// are shared out by total voting power rather than per bonded denom.
+func (h Hooks) OnDelegationSharesModified(ctx sdk.Context, delAddr sdk.AccAddress, valAddr sdk.ValAddress, denom string) {
+	val := h.k.stakeKeeper.Validator(ctx, valAddr)
+	del := h.k.stakeKeeper.Delegation(ctx, delAddr, valAddr)
+	if _, err := h.k.withdrawDelegationRewards(ctx, val, del); err != nil {
+		panic(err)
+	}
+	h.k.initializeDelegation(ctx, valAddr, delAddr, denom)
+}
+
+// OnDelegationRemoved pays out the delegation's reward accrued up to now and drops its
+// starting info, since there is no delegation left to re-initialize.
+func (h Hooks) OnDelegationRemoved(ctx sdk.Context, delAddr sdk.AccAddress, valAddr sdk.ValAddress) {
+	val := h.k.stakeKeeper.Validator(ctx, valAddr)
+	del := h.k.stakeKeeper.Delegation(ctx, delAddr, valAddr)
+	if _, err := h.k.withdrawDelegationRewards(ctx, val, del); err != nil {
+		panic(err)
+	}
+}
+
+// OnSideChainValidatorBonded mirrors OnValidatorBonded for side-chain validators
+func (h Hooks) OnSideChainValidatorBonded(ctx sdk.Context, sideConsAddr []byte, operator sdk.ValAddress) {
+	h.k.incrementValidatorPeriod(ctx, h.k.stakeKeeper.Validator(ctx, operator))
+}
+
+// OnSideChainValidatorBeginUnbonding mirrors OnValidatorBeginUnbonding for side-chain validators
+func (h Hooks) OnSideChainValidatorBeginUnbonding(ctx sdk.Context, sideConsAddr []byte, operator sdk.ValAddress) {
+	h.k.incrementValidatorPeriod(ctx, h.k.stakeKeeper.Validator(ctx, operator))
+}
+
+// OnSelfDelDropBelowMin is a no-op for reward accounting
+func (h Hooks) OnSelfDelDropBelowMin(ctx sdk.Context, operator sdk.ValAddress) {}
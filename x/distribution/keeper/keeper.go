@@ -0,0 +1,353 @@
+package keeper
+
+import (
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/distribution/types"
+)
+
+// Keeper of the distribution store, lazily accumulating and paying out
+// validator and delegator rewards using the F1 fee-distribution algorithm.
+type Keeper struct {
+	storeKey    sdk.StoreKey
+	cdc         *codec.Codec
+	bankKeeper  types.BankKeeper
+	stakeKeeper types.StakingKeeper
+
+	codespace sdk.CodespaceType
+}
+
+// NewKeeper creates a new distribution Keeper instance
+func NewKeeper(key sdk.StoreKey, cdc *codec.Codec, sk types.StakingKeeper, bk types.BankKeeper, codespace sdk.CodespaceType) Keeper {
+	return Keeper{
+		storeKey:    key,
+		cdc:         cdc,
+		stakeKeeper: sk,
+		bankKeeper:  bk,
+		codespace:   codespace,
+	}
+}
+
+// GetValidatorCurrentRewards gets a validator's current rewards
+func (k Keeper) GetValidatorCurrentRewards(ctx sdk.Context, val sdk.ValAddress) types.ValidatorCurrentRewards {
+	store := ctx.KVStore(k.storeKey)
+	b := store.Get(types.GetValidatorCurrentRewardsKey(val))
+	if b == nil {
+		return types.ValidatorCurrentRewards{}
+	}
+	var rewards types.ValidatorCurrentRewards
+	k.cdc.MustUnmarshalBinaryLengthPrefixed(b, &rewards)
+	return rewards
+}
+
+// SetValidatorCurrentRewards sets a validator's current rewards
+func (k Keeper) SetValidatorCurrentRewards(ctx sdk.Context, val sdk.ValAddress, rewards types.ValidatorCurrentRewards) {
+	store := ctx.KVStore(k.storeKey)
+	b := k.cdc.MustMarshalBinaryLengthPrefixed(rewards)
+	store.Set(types.GetValidatorCurrentRewardsKey(val), b)
+}
+
+// GetValidatorHistoricalRewards gets a validator's historical rewards for a given period
+func (k Keeper) GetValidatorHistoricalRewards(ctx sdk.Context, val sdk.ValAddress, period uint64) types.ValidatorHistoricalRewards {
+	store := ctx.KVStore(k.storeKey)
+	b := store.Get(types.GetValidatorHistoricalRewardsKey(val, period))
+	var rewards types.ValidatorHistoricalRewards
+	k.cdc.MustUnmarshalBinaryLengthPrefixed(b, &rewards)
+	return rewards
+}
+
+// SetValidatorHistoricalRewards sets a validator's historical rewards for a given period
+func (k Keeper) SetValidatorHistoricalRewards(ctx sdk.Context, val sdk.ValAddress, period uint64, rewards types.ValidatorHistoricalRewards) {
+	store := ctx.KVStore(k.storeKey)
+	b := k.cdc.MustMarshalBinaryLengthPrefixed(rewards)
+	store.Set(types.GetValidatorHistoricalRewardsKey(val, period), b)
+}
+
+// deleteValidatorHistoricalReward deletes a historical reward record once its reference count reaches zero
+func (k Keeper) deleteValidatorHistoricalReward(ctx sdk.Context, val sdk.ValAddress, period uint64) {
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(types.GetValidatorHistoricalRewardsKey(val, period))
+}
+
+// incrementReferenceCount increments the reference count on a historical rewards entry
+func (k Keeper) incrementReferenceCount(ctx sdk.Context, val sdk.ValAddress, period uint64) {
+	historical := k.GetValidatorHistoricalRewards(ctx, val, period)
+	historical.ReferenceCount++
+	k.SetValidatorHistoricalRewards(ctx, val, period, historical)
+}
+
+// decrementReferenceCount decrements the reference count on a historical rewards entry,
+// pruning it once no delegation depends on it any longer
+func (k Keeper) decrementReferenceCount(ctx sdk.Context, val sdk.ValAddress, period uint64) {
+	historical := k.GetValidatorHistoricalRewards(ctx, val, period)
+	historical.ReferenceCount--
+	if historical.ReferenceCount == 0 {
+		k.deleteValidatorHistoricalReward(ctx, val, period)
+	} else {
+		k.SetValidatorHistoricalRewards(ctx, val, period, historical)
+	}
+}
+
+// GetDelegatorStartingInfo gets the starting info a delegation last synced its rewards at
+func (k Keeper) GetDelegatorStartingInfo(ctx sdk.Context, val sdk.ValAddress, del sdk.AccAddress) types.DelegatorStartingInfo {
+	store := ctx.KVStore(k.storeKey)
+	b := store.Get(types.GetDelegatorStartingInfoKey(val, del))
+	var info types.DelegatorStartingInfo
+	k.cdc.MustUnmarshalBinaryLengthPrefixed(b, &info)
+	return info
+}
+
+// SetDelegatorStartingInfo sets the starting info a delegation last synced its rewards at
+func (k Keeper) SetDelegatorStartingInfo(ctx sdk.Context, val sdk.ValAddress, del sdk.AccAddress, info types.DelegatorStartingInfo) {
+	store := ctx.KVStore(k.storeKey)
+	b := k.cdc.MustMarshalBinaryLengthPrefixed(info)
+	store.Set(types.GetDelegatorStartingInfoKey(val, del), b)
+}
+
+// deleteDelegatorStartingInfo removes a delegation's starting info, e.g. once it is fully withdrawn
+func (k Keeper) deleteDelegatorStartingInfo(ctx sdk.Context, val sdk.ValAddress, del sdk.AccAddress) {
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(types.GetDelegatorStartingInfoKey(val, del))
+}
+
+// GetValidatorAccumulatedCommission gets a validator's unwithdrawn commission
+func (k Keeper) GetValidatorAccumulatedCommission(ctx sdk.Context, val sdk.ValAddress) types.ValidatorAccumulatedCommission {
+	store := ctx.KVStore(k.storeKey)
+	b := store.Get(types.GetValidatorAccumulatedCommissionKey(val))
+	if b == nil {
+		return types.ValidatorAccumulatedCommission{}
+	}
+	var commission types.ValidatorAccumulatedCommission
+	k.cdc.MustUnmarshalBinaryLengthPrefixed(b, &commission)
+	return commission
+}
+
+// SetValidatorAccumulatedCommission sets a validator's unwithdrawn commission
+func (k Keeper) SetValidatorAccumulatedCommission(ctx sdk.Context, val sdk.ValAddress, commission types.ValidatorAccumulatedCommission) {
+	store := ctx.KVStore(k.storeKey)
+	b := k.cdc.MustMarshalBinaryLengthPrefixed(commission)
+	store.Set(types.GetValidatorAccumulatedCommissionKey(val), b)
+}
+
+// initializeValidator sets up a brand new validator's reward tracking at period 0
+func (k Keeper) initializeValidator(ctx sdk.Context, val sdk.ValAddress) {
+	k.SetValidatorHistoricalRewards(ctx, val, 0, types.NewValidatorHistoricalRewards(sdk.DecCoins{}, 1))
+	k.SetValidatorCurrentRewards(ctx, val, types.NewValidatorCurrentRewards(sdk.DecCoins{}, 1))
+	k.SetValidatorAccumulatedCommission(ctx, val, types.ValidatorAccumulatedCommission{})
+}
+
+// incrementValidatorPeriod closes out a validator's current reward period, appending its
+// reward-per-share ratio to the historical record, and opens up the next period
+func (k Keeper) incrementValidatorPeriod(ctx sdk.Context, val sdk.Validator) uint64 {
+	rewards := k.GetValidatorCurrentRewards(ctx, val.GetOperator())
+
+	tokens := val.GetTokens()
+	var current sdk.DecCoins
+	if tokens.IsPositive() {
+		current = rewards.Rewards.QuoDec(tokens)
+	} else {
+		current = sdk.DecCoins{}
+	}
+
+	historical := k.GetValidatorHistoricalRewards(ctx, val.GetOperator(), rewards.Period-1)
+	k.SetValidatorHistoricalRewards(ctx, val.GetOperator(), rewards.Period,
+		types.NewValidatorHistoricalRewards(historical.CumulativeRewardRatio.Add(current), 1))
+
+	k.decrementReferenceCount(ctx, val.GetOperator(), rewards.Period-1)
+	k.SetValidatorCurrentRewards(ctx, val.GetOperator(), types.NewValidatorCurrentRewards(sdk.DecCoins{}, rewards.Period+1))
+
+	return rewards.Period
+}
+
+// initializeDelegation records the period and stake a delegation starts accruing rewards
+// from. denom records which bonded denom's share change (if any) triggered this resync;
+// reward accounting itself is still computed off the aggregate GetShares()/GetTokens()
+// view, since fees are shared out by total voting power rather than per bonded denom.
+func (k Keeper) initializeDelegation(ctx sdk.Context, val sdk.ValAddress, del sdk.AccAddress, denom string) {
+	validator := k.stakeKeeper.Validator(ctx, val)
+	delegation := k.stakeKeeper.Delegation(ctx, del, val)
+
+	previousPeriod := k.GetValidatorCurrentRewards(ctx, val).Period - 1
+	k.incrementReferenceCount(ctx, val, previousPeriod)
+
+	stake := validator.TokensFromShares(delegation.GetShares())
+	k.SetDelegatorStartingInfo(ctx, val, del, types.NewDelegatorStartingInfo(previousPeriod, stake, ctx.BlockHeight(), denom))
+}
+
+// calculateDelegationRewards computes the rewards accrued by a delegation between its
+// starting period and endingPeriod, which must already be closed out
+func (k Keeper) calculateDelegationRewards(ctx sdk.Context, val sdk.Validator, del sdk.Delegation, endingPeriod uint64) sdk.DecCoins {
+	startingInfo := k.GetDelegatorStartingInfo(ctx, val.GetOperator(), del.GetDelegatorAddr())
+
+	starting := k.GetValidatorHistoricalRewards(ctx, val.GetOperator(), startingInfo.PreviousPeriod)
+	ending := k.GetValidatorHistoricalRewards(ctx, val.GetOperator(), endingPeriod)
+
+	difference := ending.CumulativeRewardRatio.Sub(starting.CumulativeRewardRatio)
+	stake := k.applySlashEvents(ctx, val.GetOperator(), startingInfo, endingPeriod)
+
+	return difference.MulDec(stake)
+}
+
+// applySlashEvents discounts a delegation's recorded starting stake for every slash
+// that occurred on the validator between the delegation's starting and ending periods
+func (k Keeper) applySlashEvents(ctx sdk.Context, val sdk.ValAddress, startingInfo types.DelegatorStartingInfo, endingPeriod uint64) sdk.Dec {
+	stake := startingInfo.Stake
+	k.IterateValidatorSlashEventsBetween(ctx, val, startingInfo.PreviousPeriod, endingPeriod,
+		func(event types.ValidatorSlashEvent) (stop bool) {
+			stake = stake.Mul(sdk.OneDec().Sub(event.Fraction))
+			return false
+		},
+	)
+	return stake
+}
+
+// IterateValidatorSlashEventsBetween iterates over a validator's slash events whose
+// recorded period falls between startingPeriod (exclusive) and endingPeriod (inclusive)
+func (k Keeper) IterateValidatorSlashEventsBetween(ctx sdk.Context, val sdk.ValAddress, startingPeriod, endingPeriod uint64,
+	fn func(event types.ValidatorSlashEvent) (stop bool)) {
+	store := ctx.KVStore(k.storeKey)
+	for period := startingPeriod + 1; period <= endingPeriod; period++ {
+		b := store.Get(types.GetValidatorSlashEventKey(val, period))
+		if b == nil {
+			continue
+		}
+		var event types.ValidatorSlashEvent
+		k.cdc.MustUnmarshalBinaryLengthPrefixed(b, &event)
+		if fn(event) {
+			break
+		}
+	}
+}
+
+// RecordSlashEvent records that a validator was slashed by fraction while validatorPeriod
+// was the validator's current, still-open period
+func (k Keeper) RecordSlashEvent(ctx sdk.Context, val sdk.ValAddress, validatorPeriod uint64, fraction sdk.Dec) {
+	store := ctx.KVStore(k.storeKey)
+	event := types.NewValidatorSlashEvent(validatorPeriod, fraction)
+	b := k.cdc.MustMarshalBinaryLengthPrefixed(event)
+	store.Set(types.GetValidatorSlashEventKey(val, validatorPeriod), b)
+}
+
+// CalculateDelegationRewards previews the rewards currently outstanding for a delegation
+// without modifying any state; used by the query endpoints. The validator's currently
+// open period hasn't been folded into its historical ratio yet, so this runs the same
+// period-closing step withdrawDelegationRewards relies on, but against a cached context
+// that's discarded afterwards instead of the real store.
+func (k Keeper) CalculateDelegationRewards(ctx sdk.Context, val sdk.ValAddress, del sdk.AccAddress) (sdk.DecCoins, sdk.Error) {
+	validator := k.stakeKeeper.Validator(ctx, val)
+	if validator == nil {
+		return nil, sdk.ErrUnknownRequest("unknown validator")
+	}
+	delegation := k.stakeKeeper.Delegation(ctx, del, val)
+	if delegation == nil {
+		return nil, sdk.ErrUnknownRequest("no delegation for (address, validator) tuple")
+	}
+
+	cacheCtx, _ := ctx.CacheContext()
+	endingPeriod := k.incrementValidatorPeriod(cacheCtx, validator)
+	return k.calculateDelegationRewards(cacheCtx, validator, delegation, endingPeriod), nil
+}
+
+// withdrawDelegationRewards pays out a delegation's outstanding rewards and clears its
+// starting info. It does not re-initialize the delegation's starting info at the new
+// current period - callers that keep the delegation alive (a resync, a share change) must
+// call initializeDelegation themselves afterwards; callers that are removing the
+// delegation entirely must not.
+func (k Keeper) withdrawDelegationRewards(ctx sdk.Context, val sdk.Validator, del sdk.Delegation) (sdk.Coins, sdk.Error) {
+	endingPeriod := k.incrementValidatorPeriod(ctx, val)
+	rewards := k.calculateDelegationRewards(ctx, val, del, endingPeriod)
+
+	k.decrementReferenceCount(ctx, val.GetOperator(), k.GetDelegatorStartingInfo(ctx, val.GetOperator(), del.GetDelegatorAddr()).PreviousPeriod)
+	k.deleteDelegatorStartingInfo(ctx, val.GetOperator(), del.GetDelegatorAddr())
+
+	truncated, remainder := rewards.TruncateDecimal()
+	if !remainder.IsZero() {
+		// the fractional remainder left behind by truncating to whole coins isn't
+		// owed to this delegation any more (its starting info is gone), but it's
+		// still real, not-yet-distributed reward - credit it back onto the
+		// validator's new current rewards instead of dropping it, mirroring how
+		// WithdrawValidatorCommission keeps its own remainder.
+		current := k.GetValidatorCurrentRewards(ctx, val.GetOperator())
+		current.Rewards = current.Rewards.Add(remainder)
+		k.SetValidatorCurrentRewards(ctx, val.GetOperator(), current)
+	}
+	if !truncated.IsZero() {
+		if err := k.bankKeeper.SendCoins(ctx, types.PoolAddress, del.GetDelegatorAddr(), truncated); err != nil {
+			return nil, err
+		}
+	}
+
+	return truncated, nil
+}
+
+// WithdrawDelegationRewards withdraws the rewards accrued by delAddr's delegation to valAddr
+// and resyncs its starting info to the validator's new current period
+func (k Keeper) WithdrawDelegationRewards(ctx sdk.Context, delAddr sdk.AccAddress, valAddr sdk.ValAddress) (sdk.Coins, sdk.Error) {
+	val := k.stakeKeeper.Validator(ctx, valAddr)
+	if val == nil {
+		return nil, sdk.ErrUnknownRequest("unknown validator")
+	}
+	del := k.stakeKeeper.Delegation(ctx, delAddr, valAddr)
+	if del == nil {
+		return nil, sdk.ErrUnknownRequest("no delegation for (address, validator) tuple")
+	}
+	truncated, err := k.withdrawDelegationRewards(ctx, val, del)
+	if err != nil {
+		return nil, err
+	}
+	k.initializeDelegation(ctx, valAddr, delAddr, "")
+	return truncated, nil
+}
+
+// WithdrawValidatorCommission withdraws the whole-coin part of a validator's accumulated
+// commission to its declared fee address; the fractional remainder stays accumulated
+func (k Keeper) WithdrawValidatorCommission(ctx sdk.Context, valAddr sdk.ValAddress) (sdk.Coins, sdk.Error) {
+	val := k.stakeKeeper.Validator(ctx, valAddr)
+	if val == nil {
+		return nil, sdk.ErrUnknownRequest("unknown validator")
+	}
+
+	commission := k.GetValidatorAccumulatedCommission(ctx, valAddr)
+	truncated, remainder := sdk.DecCoins(commission).TruncateDecimal()
+	k.SetValidatorAccumulatedCommission(ctx, valAddr, types.ValidatorAccumulatedCommission(remainder))
+
+	if !truncated.IsZero() {
+		if err := k.bankKeeper.SendCoins(ctx, types.PoolAddress, val.GetFeeAddr(), truncated); err != nil {
+			return nil, err
+		}
+	}
+	return truncated, nil
+}
+
+// AllocateTokens moves this block's collected fees out of feeCollector and into the
+// module's pool address, then divides them across the bonded validator set, crediting
+// each validator's current rewards and commission in proportion to its power. Fees sit in
+// the pool, custodied but unassigned to any one delegator, until withdrawn.
+func (k Keeper) AllocateTokens(ctx sdk.Context, totalPower sdk.Int, feeCollector sdk.AccAddress, collected sdk.Coins) sdk.Error {
+	if !totalPower.IsPositive() || collected.IsZero() {
+		return nil
+	}
+	if err := k.bankKeeper.SendCoins(ctx, feeCollector, types.PoolAddress, collected); err != nil {
+		return err
+	}
+
+	fees := sdk.NewDecCoins(collected)
+	totalPowerDec := sdk.NewDecFromInt(totalPower)
+	k.stakeKeeper.IterateValidatorsBonded(ctx, func(_ int64, val sdk.Validator) (stop bool) {
+		powerFraction := sdk.NewDecFromInt(val.GetPower()).Quo(totalPowerDec)
+		reward := fees.MulDec(powerFraction)
+
+		commission := reward.MulDec(val.GetCommission())
+		shared := reward.Sub(commission)
+
+		currentCommission := k.GetValidatorAccumulatedCommission(ctx, val.GetOperator())
+		k.SetValidatorAccumulatedCommission(ctx, val.GetOperator(), types.ValidatorAccumulatedCommission(sdk.DecCoins(currentCommission).Add(commission)))
+
+		current := k.GetValidatorCurrentRewards(ctx, val.GetOperator())
+		current.Rewards = current.Rewards.Add(shared)
+		k.SetValidatorCurrentRewards(ctx, val.GetOperator(), current)
+
+		return false
+	})
+	return nil
+}
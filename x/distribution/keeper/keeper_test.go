@@ -0,0 +1,298 @@
+package keeper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto"
+	dbm "github.com/tendermint/tendermint/libs/db"
+	"github.com/tendermint/tendermint/libs/log"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/store"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// mockValidator is the minimal sdk.Validator double needed to drive the
+// accumulator; unused getters return zero values.
+type mockValidator struct {
+	operator        sdk.ValAddress
+	feeAddr         sdk.AccAddress
+	tokens          sdk.Dec
+	power           sdk.Int
+	commission      sdk.Dec
+	delegatorShares sdk.Dec
+}
+
+func (v mockValidator) GetJailed() bool                   { return false }
+func (v mockValidator) GetMoniker() string                { return "" }
+func (v mockValidator) GetStatus() sdk.BondStatus         { return sdk.Bonded }
+func (v mockValidator) GetFeeAddr() sdk.AccAddress        { return v.feeAddr }
+func (v mockValidator) GetOperator() sdk.ValAddress       { return v.operator }
+func (v mockValidator) GetConsPubKey() crypto.PubKey      { return nil }
+func (v mockValidator) GetConsAddr() sdk.ConsAddress      { return sdk.ConsAddress(v.operator) }
+func (v mockValidator) GetPower() sdk.Int                 { return v.power }
+func (v mockValidator) GetTokens() sdk.Dec                { return v.tokens }
+func (v mockValidator) GetTokensByDenom(_ string) sdk.Dec { return v.tokens }
+func (v mockValidator) GetBondedCoins() sdk.Coins         { return nil }
+func (v mockValidator) TokensFromShares(shares sdk.Dec) sdk.Dec {
+	// keep the math simple: one token per share
+	return shares
+}
+func (v mockValidator) GetCommission() sdk.Dec       { return v.commission }
+func (v mockValidator) GetDelegatorShares() sdk.Dec  { return v.delegatorShares }
+func (v mockValidator) GetBondHeight() int64         { return 0 }
+func (v mockValidator) GetSideChainConsAddr() []byte { return nil }
+func (v mockValidator) GetSideChainVoteAddr() []byte { return nil }
+func (v mockValidator) IsSideChainValidator() bool   { return false }
+func (v mockValidator) GetIntraTxCounter() int16     { return 0 }
+
+type mockDelegation struct {
+	delAddr sdk.AccAddress
+	valAddr sdk.ValAddress
+	shares  sdk.Dec
+}
+
+func (d mockDelegation) GetDelegatorAddr() sdk.AccAddress  { return d.delAddr }
+func (d mockDelegation) GetValidatorAddr() sdk.ValAddress  { return d.valAddr }
+func (d mockDelegation) GetShares() sdk.Dec                { return d.shares }
+func (d mockDelegation) GetSharesByDenom(_ string) sdk.Dec { return d.shares }
+
+// mockStakingKeeper serves a fixed set of validators/delegations out of memory;
+// tests mutate the maps directly to simulate delegation/slashing events.
+type mockStakingKeeper struct {
+	validators  map[string]mockValidator
+	delegations map[string]mockDelegation
+}
+
+func newMockStakingKeeper() *mockStakingKeeper {
+	return &mockStakingKeeper{
+		validators:  map[string]mockValidator{},
+		delegations: map[string]mockDelegation{},
+	}
+}
+
+func (k *mockStakingKeeper) Validator(_ sdk.Context, addr sdk.ValAddress) sdk.Validator {
+	v, ok := k.validators[addr.String()]
+	if !ok {
+		return nil
+	}
+	return v
+}
+
+func (k *mockStakingKeeper) Delegation(_ sdk.Context, delAddr sdk.AccAddress, valAddr sdk.ValAddress) sdk.Delegation {
+	d, ok := k.delegations[valAddr.String()+delAddr.String()]
+	if !ok {
+		return nil
+	}
+	return d
+}
+
+func (k *mockStakingKeeper) IterateValidatorsBonded(ctx sdk.Context, fn func(index int64, validator sdk.Validator) (stop bool)) {
+	i := int64(0)
+	for _, v := range k.validators {
+		if fn(i, v) {
+			return
+		}
+		i++
+	}
+}
+
+// mockBankKeeper just records transfers so tests can assert on who got paid what.
+type mockBankKeeper struct {
+	sent       []sdk.Coins
+	recipients []sdk.AccAddress
+}
+
+func (k *mockBankKeeper) SendCoins(_ sdk.Context, _, to sdk.AccAddress, amt sdk.Coins) sdk.Error {
+	k.sent = append(k.sent, amt)
+	k.recipients = append(k.recipients, to)
+	return nil
+}
+
+func setupTestKeeper(t *testing.T) (sdk.Context, Keeper, *mockStakingKeeper, *mockBankKeeper) {
+	storeKey := sdk.NewKVStoreKey("distr")
+	ms := store.NewCommitMultiStore(dbm.NewMemDB())
+	ms.MountStoreWithDB(storeKey, sdk.StoreTypeIAVL, nil)
+	require.NoError(t, ms.LoadLatestVersion())
+
+	ctx := sdk.NewContext(ms, abci.Header{}, false, log.NewNopLogger())
+	cdc := codec.New()
+
+	sk := newMockStakingKeeper()
+	bk := &mockBankKeeper{}
+	k := NewKeeper(storeKey, cdc, sk, bk, sdk.CodespaceType("distr"))
+	return ctx, k, sk, bk
+}
+
+func valAddr(b byte) sdk.ValAddress { return sdk.ValAddress([]byte{b}) }
+func accAddr(b byte) sdk.AccAddress { return sdk.AccAddress([]byte{b}) }
+
+// TestWithdrawImmediatelyAfterBondingYieldsZero covers the invariant called out in the
+// request: a delegation withdrawing right after its starting info is initialized, with no
+// fees allocated in between, must see zero outstanding reward.
+func TestWithdrawImmediatelyAfterBondingYieldsZero(t *testing.T) {
+	ctx, k, sk, _ := setupTestKeeper(t)
+
+	val := mockValidator{operator: valAddr(1), feeAddr: accAddr(1), tokens: sdk.NewDec(100), power: sdk.NewInt(100), commission: sdk.ZeroDec(), delegatorShares: sdk.NewDec(100)}
+	del := mockDelegation{delAddr: accAddr(2), valAddr: valAddr(1), shares: sdk.NewDec(100)}
+	sk.validators[val.operator.String()] = val
+	sk.delegations[val.operator.String()+del.delAddr.String()] = del
+
+	k.initializeValidator(ctx, val.operator)
+	k.initializeDelegation(ctx, val.operator, del.delAddr, "")
+
+	rewards, err := k.CalculateDelegationRewards(ctx, val.operator, del.delAddr)
+	require.Nil(t, err)
+	require.True(t, rewards.IsZero(), "expected zero reward immediately after bonding, got %s", rewards)
+}
+
+// TestAllocateTokensConservation checks that fees allocated to a validator end up either
+// in the validator's accumulated commission or a delegator's outstanding reward, and
+// nothing is silently lost.
+func TestAllocateTokensConservation(t *testing.T) {
+	ctx, k, sk, _ := setupTestKeeper(t)
+
+	val := mockValidator{operator: valAddr(1), feeAddr: accAddr(1), tokens: sdk.NewDec(100), power: sdk.NewInt(100), commission: sdk.NewDecWithPrec(1, 1), delegatorShares: sdk.NewDec(100)}
+	del := mockDelegation{delAddr: accAddr(2), valAddr: valAddr(1), shares: sdk.NewDec(100)}
+	sk.validators[val.operator.String()] = val
+	sk.delegations[val.operator.String()+del.delAddr.String()] = del
+
+	k.initializeValidator(ctx, val.operator)
+	k.initializeDelegation(ctx, val.operator, del.delAddr, "")
+
+	collected := sdk.Coins{sdk.NewCoin("stake", 100)}
+	require.Nil(t, k.AllocateTokens(ctx, sdk.NewInt(100), accAddr(3), collected))
+
+	delegatorRewards, err := k.CalculateDelegationRewards(ctx, val.operator, del.delAddr)
+	require.Nil(t, err)
+	commission := sdk.DecCoins(k.GetValidatorAccumulatedCommission(ctx, val.operator))
+
+	total := delegatorRewards.Add(commission)
+	fees := sdk.NewDecCoins(collected)
+	require.True(t, total.IsEqual(fees), "expected commission + outstanding reward to equal fees allocated, got %s want %s", total, fees)
+}
+
+// TestOnDelegationSharesModifiedPaysOutstandingReward is a regression test: before the
+// fix, changing a delegation's shares (redelegate/partial-undelegate) silently forfeited
+// whatever reward had accrued up to that point instead of paying it out.
+func TestOnDelegationSharesModifiedPaysOutstandingReward(t *testing.T) {
+	ctx, k, sk, bk := setupTestKeeper(t)
+	h := k.Hooks()
+
+	val := mockValidator{operator: valAddr(1), feeAddr: accAddr(1), tokens: sdk.NewDec(100), power: sdk.NewInt(100), commission: sdk.ZeroDec(), delegatorShares: sdk.NewDec(100)}
+	del := mockDelegation{delAddr: accAddr(2), valAddr: valAddr(1), shares: sdk.NewDec(100)}
+	sk.validators[val.operator.String()] = val
+	sk.delegations[val.operator.String()+del.delAddr.String()] = del
+
+	k.initializeValidator(ctx, val.operator)
+	k.initializeDelegation(ctx, val.operator, del.delAddr, "")
+
+	collected := sdk.Coins{sdk.NewCoin("stake", 100)}
+	require.Nil(t, k.AllocateTokens(ctx, sdk.NewInt(100), accAddr(3), collected))
+
+	h.OnDelegationSharesModified(ctx, del.delAddr, val.operator, "stake")
+
+	// one transfer sweeps the collected fees into the pool, the other pays the
+	// delegator out of it
+	require.Len(t, bk.sent, 2, "expected the accrued reward to be paid out, not forfeited")
+	remaining, err := k.CalculateDelegationRewards(ctx, val.operator, del.delAddr)
+	require.Nil(t, err)
+	require.True(t, remaining.IsZero(), "expected reward to be fully synced after the shares-modified hook, got %s", remaining)
+
+	startingInfo := k.GetDelegatorStartingInfo(ctx, val.operator, del.delAddr)
+	require.Equal(t, "stake", startingInfo.Denom, "expected the changed denom to be recorded on the new starting info")
+}
+
+// TestWithdrawValidatorCommissionPaysFeeAddr is a regression test: before the fix,
+// commission was "sent" from the validator's operator address to itself, which never
+// actually moved funds to the validator's declared fee address.
+func TestWithdrawValidatorCommissionPaysFeeAddr(t *testing.T) {
+	ctx, k, sk, bk := setupTestKeeper(t)
+
+	val := mockValidator{operator: valAddr(1), feeAddr: accAddr(9), tokens: sdk.NewDec(100), power: sdk.NewInt(100), commission: sdk.NewDec(1), delegatorShares: sdk.NewDec(100)}
+	sk.validators[val.operator.String()] = val
+
+	k.initializeValidator(ctx, val.operator)
+	require.Nil(t, k.AllocateTokens(ctx, sdk.NewInt(100), accAddr(3), sdk.Coins{sdk.NewCoin("stake", 100)}))
+
+	paid, err := k.WithdrawValidatorCommission(ctx, val.operator)
+	require.Nil(t, err)
+	require.False(t, paid.IsZero())
+
+	// one transfer sweeps the collected fees into the pool, the other pays
+	// the validator's fee address out of it
+	require.Len(t, bk.sent, 2)
+	require.Equal(t, val.feeAddr, bk.recipients[1], "expected commission to be paid to the validator's fee address")
+}
+
+// TestApplySlashEventsDiscountsIdenticalDelegationsEqually exercises RecordSlashEvent
+// directly and checks the invariant called out in the request: two delegations that
+// joined at the same period and are evaluated over the same span must see identical
+// rewards, discounted by the same fraction, regardless of an intervening slash.
+func TestApplySlashEventsDiscountsIdenticalDelegationsEqually(t *testing.T) {
+	ctx, k, sk, _ := setupTestKeeper(t)
+
+	val := mockValidator{operator: valAddr(1), feeAddr: accAddr(1), tokens: sdk.NewDec(100), power: sdk.NewInt(100), commission: sdk.ZeroDec(), delegatorShares: sdk.NewDec(100)}
+	delA := mockDelegation{delAddr: accAddr(2), valAddr: valAddr(1), shares: sdk.NewDec(50)}
+	delB := mockDelegation{delAddr: accAddr(3), valAddr: valAddr(1), shares: sdk.NewDec(50)}
+	sk.validators[val.operator.String()] = val
+	sk.delegations[val.operator.String()+delA.delAddr.String()] = delA
+	sk.delegations[val.operator.String()+delB.delAddr.String()] = delB
+
+	k.initializeValidator(ctx, val.operator)
+	k.initializeDelegation(ctx, val.operator, delA.delAddr, "")
+	k.initializeDelegation(ctx, val.operator, delB.delAddr, "")
+
+	collected := sdk.Coins{sdk.NewCoin("stake", 100)}
+	require.Nil(t, k.AllocateTokens(ctx, sdk.NewInt(100), accAddr(9), collected))
+
+	// slash the validator by 50% while period 1 (the period both delegations started
+	// tracking from) is still open
+	k.RecordSlashEvent(ctx, val.operator, 1, sdk.NewDecWithPrec(5, 1))
+
+	endingPeriod := k.incrementValidatorPeriod(ctx, val)
+
+	rewardsA := k.calculateDelegationRewards(ctx, val, delA, endingPeriod)
+	rewardsB := k.calculateDelegationRewards(ctx, val, delB, endingPeriod)
+
+	require.True(t, rewardsA.IsEqual(rewardsB),
+		"identical delegations spanning the same slash should see identical rewards, got %s and %s", rewardsA, rewardsB)
+
+	expected := sdk.NewDecCoins(sdk.Coins{sdk.NewCoin("stake", 25)})
+	require.True(t, rewardsA.IsEqual(expected),
+		"expected each 50-share delegation's reward to be discounted by the recorded slash fraction, got %s want %s", rewardsA, expected)
+}
+
+// TestWithdrawDelegationRewardsCreditsTruncationRemainder is a regression test: before
+// the fix, the fractional dust left over from truncating a delegation's reward to whole
+// coins was silently dropped instead of being credited back, unlike
+// WithdrawValidatorCommission, which keeps its own remainder in
+// ValidatorAccumulatedCommission.
+func TestWithdrawDelegationRewardsCreditsTruncationRemainder(t *testing.T) {
+	ctx, k, sk, bk := setupTestKeeper(t)
+
+	val := mockValidator{operator: valAddr(1), feeAddr: accAddr(1), tokens: sdk.NewDec(100), power: sdk.NewInt(100), commission: sdk.ZeroDec(), delegatorShares: sdk.NewDec(100)}
+	del := mockDelegation{delAddr: accAddr(2), valAddr: valAddr(1), shares: sdk.NewDec(1)}
+	sk.validators[val.operator.String()] = val
+	sk.delegations[val.operator.String()+del.delAddr.String()] = del
+
+	k.initializeValidator(ctx, val.operator)
+	k.initializeDelegation(ctx, val.operator, del.delAddr, "")
+
+	// 1 stake split over 100 power gives this 1-share delegation a 0.01 stake reward -
+	// smaller than the smallest whole coin.
+	require.Nil(t, k.AllocateTokens(ctx, sdk.NewInt(100), accAddr(9), sdk.Coins{sdk.NewCoin("stake", 1)}))
+
+	paid, err := k.WithdrawDelegationRewards(ctx, del.delAddr, val.operator)
+	require.Nil(t, err)
+	require.True(t, paid.IsZero(), "expected the sub-unit reward to truncate to nothing paid out, got %s", paid)
+	require.Len(t, bk.sent, 1, "only AllocateTokens' sweep into the pool should have moved coins, nothing paid to the delegator")
+
+	current := k.GetValidatorCurrentRewards(ctx, val.operator)
+	expectedRemainder := sdk.NewDecCoins(sdk.Coins{sdk.NewCoin("stake", 1)}).MulDec(sdk.NewDecWithPrec(1, 2))
+	require.True(t, current.Rewards.IsEqual(expectedRemainder),
+		"expected the truncated reward's fractional remainder to be credited back onto the validator's current rewards instead of dropped, got %s want %s", current.Rewards, expectedRemainder)
+}
@@ -0,0 +1,80 @@
+package keeper
+
+import (
+	"fmt"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/distribution/types"
+)
+
+// query endpoints exposed under the distribution module's query route
+const (
+	QueryDelegatorWithdrawInfo = "delegator_withdraw_info"
+	QueryValidatorCommission   = "validator_commission"
+)
+
+// QueryDelegatorWithdrawParams is the params for QueryDelegatorWithdrawInfo
+type QueryDelegatorWithdrawParams struct {
+	DelegatorAddr sdk.AccAddress
+	ValidatorAddr sdk.ValAddress
+}
+
+// QueryValidatorCommissionParams is the params for QueryValidatorCommission
+type QueryValidatorCommissionParams struct {
+	ValidatorAddr sdk.ValAddress
+}
+
+// NewQuerier creates a new querier for the distribution module, answering reward
+// and commission previews without modifying any state
+func NewQuerier(k Keeper, cdc *codec.Codec) sdk.Querier {
+	return func(ctx sdk.Context, path []string, req abci.RequestQuery) ([]byte, sdk.Error) {
+		switch path[0] {
+		case QueryDelegatorWithdrawInfo:
+			return queryDelegatorWithdrawInfo(ctx, cdc, req, k)
+		case QueryValidatorCommission:
+			return queryValidatorCommission(ctx, cdc, req, k)
+		default:
+			return nil, sdk.ErrUnknownRequest(fmt.Sprintf("unknown distribution query endpoint %s", path[0]))
+		}
+	}
+}
+
+func queryDelegatorWithdrawInfo(ctx sdk.Context, cdc *codec.Codec, req abci.RequestQuery, k Keeper) ([]byte, sdk.Error) {
+	var params QueryDelegatorWithdrawParams
+	if err := cdc.UnmarshalJSON(req.Data, &params); err != nil {
+		return nil, sdk.ErrInternal(fmt.Sprintf("failed to parse params: %s", err))
+	}
+
+	rewards, sdkErr := k.CalculateDelegationRewards(ctx, params.ValidatorAddr, params.DelegatorAddr)
+	if sdkErr != nil {
+		return nil, sdkErr
+	}
+	info := types.DelegatorWithdrawInfo{
+		DelegatorAddr: params.DelegatorAddr,
+		ValidatorAddr: params.ValidatorAddr,
+		Rewards:       rewards,
+	}
+
+	bz, err := codec.MarshalJSONIndent(cdc, info)
+	if err != nil {
+		return nil, sdk.ErrInternal(fmt.Sprintf("failed to marshal result: %s", err))
+	}
+	return bz, nil
+}
+
+func queryValidatorCommission(ctx sdk.Context, cdc *codec.Codec, req abci.RequestQuery, k Keeper) ([]byte, sdk.Error) {
+	var params QueryValidatorCommissionParams
+	if err := cdc.UnmarshalJSON(req.Data, &params); err != nil {
+		return nil, sdk.ErrInternal(fmt.Sprintf("failed to parse params: %s", err))
+	}
+
+	commission := k.GetValidatorAccumulatedCommission(ctx, params.ValidatorAddr)
+	bz, err := codec.MarshalJSONIndent(cdc, commission)
+	if err != nil {
+		return nil, sdk.ErrInternal(fmt.Sprintf("failed to marshal result: %s", err))
+	}
+	return bz, nil
+}
@@ -0,0 +1,19 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// StakingKeeper defines the expected staking keeper used to read validators and
+// delegations; implemented by x/stake's keeper.
+type StakingKeeper interface {
+	Validator(ctx sdk.Context, address sdk.ValAddress) sdk.Validator
+	Delegation(ctx sdk.Context, addrDel sdk.AccAddress, addrVal sdk.ValAddress) sdk.Delegation
+	IterateValidatorsBonded(ctx sdk.Context, fn func(index int64, validator sdk.Validator) (stop bool))
+}
+
+// BankKeeper defines the expected bank keeper used to move withdrawn rewards
+// and commission between accounts.
+type BankKeeper interface {
+	SendCoins(ctx sdk.Context, fromAddr sdk.AccAddress, toAddr sdk.AccAddress, amt sdk.Coins) sdk.Error
+}
@@ -0,0 +1,60 @@
+package types
+
+import (
+	"encoding/binary"
+
+	"github.com/tendermint/tendermint/crypto"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// ModuleName is the name of the distribution module
+const ModuleName = "distr"
+
+// PoolAddress is the deterministic account that custodies fees handed to the
+// distribution module for lazy payout. AllocateTokens moves collected fees
+// into it; WithdrawDelegationRewards and WithdrawValidatorCommission pay out
+// of it. It is derived the same way x/auth derives module accounts, so no
+// private key for it ever exists.
+var PoolAddress = sdk.AccAddress(crypto.AddressHash([]byte(ModuleName)))
+
+// store key prefixes
+var (
+	ValidatorCurrentRewardsPrefix     = []byte{0x01} // prefix for each validator's current rewards
+	ValidatorHistoricalRewardsPrefix  = []byte{0x02} // prefix for each validator's historical reward ratios, by period
+	ValidatorAccumulatedCommissionKey = []byte{0x03} // prefix for each validator's accumulated, unwithdrawn commission
+	DelegatorStartingInfoPrefix       = []byte{0x04} // prefix for each delegation's starting info
+	ValidatorSlashEventPrefix         = []byte{0x05} // prefix for each validator's slash events, by period
+)
+
+// GetValidatorCurrentRewardsKey gets the key for a validator's current rewards
+func GetValidatorCurrentRewardsKey(valAddr sdk.ValAddress) []byte {
+	return append(ValidatorCurrentRewardsPrefix, valAddr.Bytes()...)
+}
+
+// GetValidatorHistoricalRewardsKey gets the key for a validator's historical rewards at a given period
+func GetValidatorHistoricalRewardsKey(valAddr sdk.ValAddress, period uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, period)
+	key := append(ValidatorHistoricalRewardsPrefix, valAddr.Bytes()...)
+	return append(key, b...)
+}
+
+// GetValidatorAccumulatedCommissionKey gets the key for a validator's accumulated commission
+func GetValidatorAccumulatedCommissionKey(valAddr sdk.ValAddress) []byte {
+	return append(ValidatorAccumulatedCommissionKey, valAddr.Bytes()...)
+}
+
+// GetDelegatorStartingInfoKey gets the key for a delegator's starting info on a given validator
+func GetDelegatorStartingInfoKey(valAddr sdk.ValAddress, delAddr sdk.AccAddress) []byte {
+	key := append(DelegatorStartingInfoPrefix, valAddr.Bytes()...)
+	return append(key, delAddr.Bytes()...)
+}
+
+// GetValidatorSlashEventKey gets the key for a validator's slash event recorded at the given period
+func GetValidatorSlashEventKey(valAddr sdk.ValAddress, period uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, period)
+	key := append(ValidatorSlashEventPrefix, valAddr.Bytes()...)
+	return append(key, b...)
+}
@@ -0,0 +1,87 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// distribution message types and routes
+const (
+	MsgRoute                       = "distr"
+	TypeMsgWithdrawDelegatorReward = "withdraw_delegator_reward"
+	TypeMsgWithdrawValidatorComm   = "withdraw_validator_commission"
+)
+
+// MsgWithdrawDelegatorReward withdraws rewards for a delegation between a
+// delegator and a validator that have accrued up to the current period.
+type MsgWithdrawDelegatorReward struct {
+	DelegatorAddr sdk.AccAddress `json:"delegator_addr"`
+	ValidatorAddr sdk.ValAddress `json:"validator_addr"`
+}
+
+// NewMsgWithdrawDelegatorReward creates a MsgWithdrawDelegatorReward
+func NewMsgWithdrawDelegatorReward(delAddr sdk.AccAddress, valAddr sdk.ValAddress) MsgWithdrawDelegatorReward {
+	return MsgWithdrawDelegatorReward{
+		DelegatorAddr: delAddr,
+		ValidatorAddr: valAddr,
+	}
+}
+
+// nolint
+func (msg MsgWithdrawDelegatorReward) Route() string { return MsgRoute }
+func (msg MsgWithdrawDelegatorReward) Type() string  { return TypeMsgWithdrawDelegatorReward }
+
+// ValidateBasic implements sdk.Msg
+func (msg MsgWithdrawDelegatorReward) ValidateBasic() sdk.Error {
+	if msg.DelegatorAddr.Empty() {
+		return sdk.ErrInvalidAddress("missing delegator address")
+	}
+	if msg.ValidatorAddr.Empty() {
+		return sdk.ErrInvalidAddress("missing validator address")
+	}
+	return nil
+}
+
+// GetSignBytes implements sdk.Msg
+func (msg MsgWithdrawDelegatorReward) GetSignBytes() []byte {
+	b := MsgCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(b)
+}
+
+// GetSigners implements sdk.Msg
+func (msg MsgWithdrawDelegatorReward) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.DelegatorAddr}
+}
+
+// MsgWithdrawValidatorCommission withdraws the whole-coin part of the
+// commission accrued by a validator so far.
+type MsgWithdrawValidatorCommission struct {
+	ValidatorAddr sdk.ValAddress `json:"validator_addr"`
+}
+
+// NewMsgWithdrawValidatorCommission creates a MsgWithdrawValidatorCommission
+func NewMsgWithdrawValidatorCommission(valAddr sdk.ValAddress) MsgWithdrawValidatorCommission {
+	return MsgWithdrawValidatorCommission{ValidatorAddr: valAddr}
+}
+
+// nolint
+func (msg MsgWithdrawValidatorCommission) Route() string { return MsgRoute }
+func (msg MsgWithdrawValidatorCommission) Type() string  { return TypeMsgWithdrawValidatorComm }
+
+// ValidateBasic implements sdk.Msg
+func (msg MsgWithdrawValidatorCommission) ValidateBasic() sdk.Error {
+	if msg.ValidatorAddr.Empty() {
+		return sdk.ErrInvalidAddress("missing validator address")
+	}
+	return nil
+}
+
+// GetSignBytes implements sdk.Msg
+func (msg MsgWithdrawValidatorCommission) GetSignBytes() []byte {
+	b := MsgCdc.MustMarshalJSON(msg)
+	return sdk.MustSortJSON(b)
+}
+
+// GetSigners implements sdk.Msg
+func (msg MsgWithdrawValidatorCommission) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{sdk.AccAddress(msg.ValidatorAddr)}
+}
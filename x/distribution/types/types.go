@@ -0,0 +1,77 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// ValidatorCurrentRewards tracks the fees, net of commission, a validator has
+// accrued during the period that is still open, along with the period
+// number that will be closed the next time the validator's total shares
+// change (delegation, unbonding, redelegation or slashing).
+type ValidatorCurrentRewards struct {
+	Rewards sdk.DecCoins `json:"rewards"` // current rewards, not yet divided out by validator's total tokens
+	Period  uint64       `json:"period"`  // period this validator is currently on
+}
+
+// NewValidatorCurrentRewards creates a new ValidatorCurrentRewards
+func NewValidatorCurrentRewards(rewards sdk.DecCoins, period uint64) ValidatorCurrentRewards {
+	return ValidatorCurrentRewards{Rewards: rewards, Period: period}
+}
+
+// ValidatorHistoricalRewards is the cumulative reward-per-share ratio for a
+// validator as of the close of a particular period, together with a
+// reference count of the delegations that still depend on it. Once the
+// reference count drops to zero the record can be pruned.
+type ValidatorHistoricalRewards struct {
+	CumulativeRewardRatio sdk.DecCoins `json:"cumulative_reward_ratio"`
+	ReferenceCount        uint16       `json:"reference_count"`
+}
+
+// NewValidatorHistoricalRewards creates a new ValidatorHistoricalRewards
+func NewValidatorHistoricalRewards(cumulativeRewardRatio sdk.DecCoins, referenceCount uint16) ValidatorHistoricalRewards {
+	return ValidatorHistoricalRewards{
+		CumulativeRewardRatio: cumulativeRewardRatio,
+		ReferenceCount:        referenceCount,
+	}
+}
+
+// ValidatorSlashEvent records a slash applied to a validator while a period
+// was open, so that delegator stake spanning the slash can be discounted
+// correctly when rewards are calculated.
+type ValidatorSlashEvent struct {
+	ValidatorPeriod uint64  `json:"validator_period"` // period the slash occurred in
+	Fraction        sdk.Dec `json:"fraction"`         // slash fraction applied
+}
+
+// NewValidatorSlashEvent creates a new ValidatorSlashEvent
+func NewValidatorSlashEvent(validatorPeriod uint64, fraction sdk.Dec) ValidatorSlashEvent {
+	return ValidatorSlashEvent{ValidatorPeriod: validatorPeriod, Fraction: fraction}
+}
+
+// DelegatorStartingInfo records the period a delegation last synced its
+// rewards at, along with the token value of its shares at that point,
+// so withdrawal can compute stake * (ratio[end] - ratio[start]).
+type DelegatorStartingInfo struct {
+	PreviousPeriod uint64  `json:"previous_period"` // period at which the delegation last withdrew, or was created
+	Stake          sdk.Dec `json:"stake"`           // delegator's stake, as tracked at the starting period
+	Height         int64   `json:"height"`          // block height at which the starting info was set
+	Denom          string  `json:"denom"`           // bonded denom whose share change last triggered this resync, if any
+}
+
+// NewDelegatorStartingInfo creates a new DelegatorStartingInfo
+func NewDelegatorStartingInfo(previousPeriod uint64, stake sdk.Dec, height int64, denom string) DelegatorStartingInfo {
+	return DelegatorStartingInfo{PreviousPeriod: previousPeriod, Stake: stake, Height: height, Denom: denom}
+}
+
+// ValidatorAccumulatedCommission stores the commission a validator has
+// accrued but not yet withdrawn.
+type ValidatorAccumulatedCommission sdk.DecCoins
+
+// DelegatorWithdrawInfo preview of rewards outstanding for a delegator on a
+// particular validator, returned by the read-only query endpoints and never
+// persisted to the store.
+type DelegatorWithdrawInfo struct {
+	DelegatorAddr sdk.AccAddress `json:"delegator_addr"`
+	ValidatorAddr sdk.ValAddress `json:"validator_addr"`
+	Rewards       sdk.DecCoins   `json:"rewards"`
+}
@@ -4,7 +4,18 @@ import (
 	"github.com/cosmos/cosmos-sdk/codec"
 )
 
-// Register concrete types on codec codec
+// RegisterCodec registers this module's Msg* and Params types on cdc for amino
+// (de)serialization.
+//
+// TODO(stake): split each Msg's combined Route()+Type() identifier into
+// separate Route() (module dispatch key, e.g. "stake") and Type() (semantic
+// action name, e.g. "create_validator") methods, plus the accompanying
+// ante-handler/tag-emission updates and the one-release deprecation shim.
+// Not started: the Msg* struct definitions those methods would live on are
+// not present anywhere in this chunk's tree (only this registration call
+// referencing them is), so there is nothing here to attach a real Route()/
+// Type() implementation to without inventing dead code. Re-file this against
+// whichever tree owns x/stake/types/msg*.go - it doesn't belong to this chunk.
 func RegisterCodec(cdc *codec.Codec) {
 	cdc.RegisterConcrete(MsgCreateValidator{}, "cosmos-sdk/MsgCreateValidator", nil)
 	cdc.RegisterConcrete(MsgCreateValidatorOpen{}, "cosmos-sdk/MsgCreateValidatorOpen", nil)